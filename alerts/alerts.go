@@ -0,0 +1,143 @@
+// Package alerts evaluates rules over periodic process samples and fires
+// notifications through pluggable notifiers (webhook, exec, syslog) when a
+// rule's condition is met. It knows nothing about where a Sample comes from
+// — callers adapt their own report type into one on every tick.
+package alerts
+
+import "time"
+
+// Sample is the minimal set of fields a Rule can evaluate against. Callers
+// adapt their own report type (e.g. zeekmon's IntervalReport) into a Sample
+// on each tick, keeping this package free of any dependency on where that
+// data originally came from.
+type Sample struct {
+	Role            string
+	Timestamp       time.Time
+	CurrentRate     float64
+	TimesRestarted  uint64
+	RSSBytes        int64
+	VirtMemoryBytes uint64
+}
+
+// ConditionKind selects how a Rule's Threshold is interpreted.
+type ConditionKind string
+
+const (
+	// Threshold fires once Field compares against Threshold via Op
+	// continuously for at least the rule's For duration, e.g.
+	// "CurrentRate > 0.95 for 2m" or "RSSBytes > 4GiB" (For: 0).
+	Threshold ConditionKind = "threshold"
+	// Increase fires when Field has increased by more than Threshold
+	// within the rule's Within window, e.g.
+	// "TimesRestarted increase > 3 in 10m".
+	Increase ConditionKind = "increase"
+	// Growth fires when Field has grown by more than Threshold, expressed
+	// as a fraction, per the rule's Per duration, e.g.
+	// "VirtMemoryBytes growth > 10%/h".
+	Growth ConditionKind = "growth"
+)
+
+// ValidateKind reports whether kind is one of Threshold/Increase/Growth.
+func ValidateKind(kind ConditionKind) bool {
+	switch kind {
+	case Threshold, Increase, Growth:
+		return true
+	default:
+		return false
+	}
+}
+
+// Op is a comparison operator used by Threshold and Increase/Growth rules.
+type Op string
+
+const (
+	GreaterThan   Op = ">"
+	GreaterOrEqal Op = ">="
+	LessThan      Op = "<"
+	LessOrEqual   Op = "<="
+)
+
+// ValidateOp reports whether op is one of the four known comparison
+// operators.
+func ValidateOp(op Op) bool {
+	switch op {
+	case GreaterThan, GreaterOrEqal, LessThan, LessOrEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rule describes a single alerting condition over one Sample field,
+// optionally scoped to a single role, and the notifiers to invoke when it
+// fires.
+type Rule struct {
+	Name string
+	// Role restricts this rule to samples with a matching Role; empty
+	// matches every role.
+	Role      string
+	Field     string
+	Kind      ConditionKind
+	Op        Op
+	Threshold float64
+	// For is the minimum duration a Threshold condition must hold
+	// continuously before the rule fires.
+	For time.Duration
+	// Within is the lookback window an Increase rule computes its delta
+	// over.
+	Within time.Duration
+	// Per is the duration a Growth rule's rate is normalized to.
+	Per time.Duration
+
+	Notifiers []Notifier
+}
+
+// knownFields lists every Sample field a Rule may reference; validated
+// against at load time by ValidateField so a typo'd field name fails
+// loudly instead of silently comparing against 0 forever.
+var knownFields = map[string]bool{
+	"CurrentRate":     true,
+	"TimesRestarted":  true,
+	"RSSBytes":        true,
+	"VirtMemoryBytes": true,
+}
+
+// ValidateField reports whether field is a Sample field a Rule can
+// reference.
+func ValidateField(field string) bool {
+	return knownFields[field]
+}
+
+// fieldValue extracts Field's value out of s. Callers must validate Field
+// against ValidateField before building a Rule; this only handles the
+// fields ValidateField accepts.
+func fieldValue(s Sample, field string) float64 {
+	switch field {
+	case "CurrentRate":
+		return s.CurrentRate
+	case "TimesRestarted":
+		return float64(s.TimesRestarted)
+	case "RSSBytes":
+		return float64(s.RSSBytes)
+	case "VirtMemoryBytes":
+		return float64(s.VirtMemoryBytes)
+	default:
+		return 0
+	}
+}
+
+// compare applies op between a and b.
+func compare(op Op, a, b float64) bool {
+	switch op {
+	case GreaterThan:
+		return a > b
+	case GreaterOrEqal:
+		return a >= b
+	case LessThan:
+		return a < b
+	case LessOrEqual:
+		return a <= b
+	default:
+		return false
+	}
+}
@@ -0,0 +1,173 @@
+package alerts
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// notifierConfig is the on-disk representation of a single notifier
+// attached to a rule. Exactly one of Webhook/Exec/Syslog should be set,
+// selected by Kind.
+type notifierConfig struct {
+	Kind string `yaml:"kind"` // "webhook", "exec" or "syslog"
+
+	// RateLimit/Burst configure the token-bucket limiter every notifier is
+	// wrapped in, defaulting to 1 event/sec with a burst of 1.
+	RateLimit float64 `yaml:"rate_limit,omitempty"`
+	Burst     int     `yaml:"burst,omitempty"`
+
+	URL string `yaml:"url,omitempty"` // kind=webhook
+
+	Command string   `yaml:"command,omitempty"` // kind=exec
+	Args    []string `yaml:"args,omitempty"`    // kind=exec
+
+	SyslogTag      string `yaml:"syslog_tag,omitempty"`      // kind=syslog
+	SyslogFacility string `yaml:"syslog_facility,omitempty"` // kind=syslog, e.g. "daemon"
+}
+
+// ruleConfig is the on-disk representation of a single Rule.
+type ruleConfig struct {
+	Name      string           `yaml:"name"`
+	Role      string           `yaml:"role,omitempty"`
+	Field     string           `yaml:"field"`
+	Kind      string           `yaml:"kind"` // "threshold", "increase" or "growth"
+	Op        string           `yaml:"op"`
+	Threshold float64          `yaml:"threshold"`
+	For       time.Duration    `yaml:"for,omitempty"`
+	Within    time.Duration    `yaml:"within,omitempty"`
+	Per       time.Duration    `yaml:"per,omitempty"`
+	Notifiers []notifierConfig `yaml:"notifiers"`
+}
+
+// rulesFile is the top-level on-disk rules file layout.
+type rulesFile struct {
+	Rules []ruleConfig `yaml:"rules"`
+}
+
+// defaultRateLimit and defaultBurst are used for any notifier config that
+// omits rate_limit/burst.
+const (
+	defaultRateLimit = 1.0
+	defaultBurst     = 1
+)
+
+// LoadRules reads a YAML rules file from path and builds the Rules
+// (including their Notifiers) it describes.
+func LoadRules(path string) ([]*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules file %s: %w", path, err)
+	}
+
+	var rf rulesFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("parse rules file %s: %w", path, err)
+	}
+
+	rules := make([]*Rule, 0, len(rf.Rules))
+	for _, rc := range rf.Rules {
+		r, err := rc.build()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (rc ruleConfig) build() (*Rule, error) {
+	if !ValidateField(rc.Field) {
+		return nil, fmt.Errorf("rule %q: unknown field %q", rc.Name, rc.Field)
+	}
+	kind := ConditionKind(rc.Kind)
+	if !ValidateKind(kind) {
+		return nil, fmt.Errorf("rule %q: unknown kind %q (expected threshold, increase or growth)", rc.Name, rc.Kind)
+	}
+	op := Op(rc.Op)
+	if !ValidateOp(op) {
+		return nil, fmt.Errorf("rule %q: unknown op %q (expected >, >=, < or <=)", rc.Name, rc.Op)
+	}
+
+	notifiers := make([]Notifier, 0, len(rc.Notifiers))
+	for _, nc := range rc.Notifiers {
+		n, err := nc.build()
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rc.Name, err)
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	return &Rule{
+		Name:      rc.Name,
+		Role:      rc.Role,
+		Field:     rc.Field,
+		Kind:      kind,
+		Op:        op,
+		Threshold: rc.Threshold,
+		For:       rc.For,
+		Within:    rc.Within,
+		Per:       rc.Per,
+		Notifiers: notifiers,
+	}, nil
+}
+
+func (nc notifierConfig) build() (Notifier, error) {
+	var (
+		n   Notifier
+		err error
+	)
+	switch nc.Kind {
+	case "webhook":
+		if nc.URL == "" {
+			return nil, fmt.Errorf("notifier kind=webhook requires url")
+		}
+		n = NewWebhookNotifier(nc.URL)
+	case "exec":
+		if nc.Command == "" {
+			return nil, fmt.Errorf("notifier kind=exec requires command")
+		}
+		n = NewExecNotifier(nc.Command, nc.Args...)
+	case "syslog":
+		facility, ferr := parseSyslogFacility(nc.SyslogFacility)
+		if ferr != nil {
+			return nil, ferr
+		}
+		n, err = NewSyslogNotifier(facility, nc.SyslogTag)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown notifier kind %q (expected webhook, exec or syslog)", nc.Kind)
+	}
+
+	rateLimit := nc.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultRateLimit
+	}
+	burst := nc.Burst
+	if burst == 0 {
+		burst = defaultBurst
+	}
+	return WithRateLimit(n, rateLimit, burst), nil
+}
+
+// parseSyslogFacility maps the handful of facility names relevant to a
+// monitoring daemon to their syslog.Priority; it defaults to LOG_DAEMON.
+func parseSyslogFacility(name string) (syslog.Priority, error) {
+	switch name {
+	case "", "daemon":
+		return syslog.LOG_DAEMON, nil
+	case "user":
+		return syslog.LOG_USER, nil
+	case "local0":
+		return syslog.LOG_LOCAL0, nil
+	case "local1":
+		return syslog.LOG_LOCAL1, nil
+	default:
+		return 0, fmt.Errorf("unknown syslog facility %q", name)
+	}
+}
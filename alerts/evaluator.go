@@ -0,0 +1,171 @@
+package alerts
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// alertState is the lifecycle of a single (rule, role) pair's condition.
+type alertState string
+
+const (
+	statePending  alertState = "pending"
+	stateFiring   alertState = "firing"
+	stateResolved alertState = "resolved"
+)
+
+// ruleState is the per-(rule, role) bookkeeping an Evaluator needs to turn
+// a raw condition check into pending/firing/resolved transitions.
+type ruleState struct {
+	state alertState
+	// since is when the condition started being continuously true, used
+	// to honor a Threshold rule's For duration.
+	since time.Time
+	// history is a short ring of recent samples, used to compute
+	// Increase/Growth deltas over their configured window.
+	history []Sample
+}
+
+// Evaluator evaluates a fixed set of Rules against a stream of Samples,
+// firing each rule's Notifiers on pending->firing and firing->resolved
+// transitions.
+type Evaluator struct {
+	rules []*Rule
+
+	mu     sync.Mutex
+	states map[string]*ruleState
+}
+
+// NewEvaluator returns an Evaluator for rules.
+func NewEvaluator(rules []*Rule) *Evaluator {
+	return &Evaluator{rules: rules, states: make(map[string]*ruleState)}
+}
+
+// Evaluate feeds s through every rule that applies to its role. It should
+// be called once per sample, on every report tick.
+func (e *Evaluator) Evaluate(ctx context.Context, s Sample) {
+	for _, r := range e.rules {
+		if r.Role != "" && r.Role != s.Role {
+			continue
+		}
+		e.evaluateRule(ctx, r, s)
+	}
+}
+
+// stateKey identifies a rule's state for a specific role, so a role-wide
+// rule (Role == "") tracks pending/firing independently per role.
+func stateKey(r *Rule, role string) string {
+	return r.Name + "/" + role
+}
+
+func (e *Evaluator) evaluateRule(ctx context.Context, r *Rule, s Sample) {
+	e.mu.Lock()
+	key := stateKey(r, s.Role)
+	st, ok := e.states[key]
+	if !ok {
+		st = &ruleState{state: stateResolved}
+		e.states[key] = st
+	}
+	st.history = appendHistory(st.history, s, windowFor(r))
+	condTrue := conditionHolds(r, st.history, s)
+	e.mu.Unlock()
+
+	switch st.state {
+	case stateResolved, statePending:
+		if !condTrue {
+			st.state = stateResolved
+			st.since = time.Time{}
+			return
+		}
+		if st.since.IsZero() {
+			st.since = s.Timestamp
+		}
+		if s.Timestamp.Sub(st.since) >= r.For {
+			st.state = stateFiring
+			notifyAll(ctx, r, s, "firing")
+		} else {
+			st.state = statePending
+		}
+	case stateFiring:
+		if condTrue {
+			return
+		}
+		st.state = stateResolved
+		st.since = time.Time{}
+		notifyAll(ctx, r, s, "resolved")
+	}
+}
+
+// windowFor returns how far back a rule needs history kept: the Increase
+// and Growth kinds need their full lookback window; Threshold rules only
+// ever look at the latest sample.
+func windowFor(r *Rule) time.Duration {
+	switch r.Kind {
+	case Increase:
+		return r.Within
+	case Growth:
+		return r.Per
+	default:
+		return 0
+	}
+}
+
+// appendHistory appends s to history and drops samples older than window,
+// relative to s.Timestamp. A zero window keeps only the latest sample.
+func appendHistory(history []Sample, s Sample, window time.Duration) []Sample {
+	history = append(history, s)
+	if window <= 0 {
+		return history[len(history)-1:]
+	}
+	cutoff := s.Timestamp.Add(-window)
+	i := 0
+	for ; i < len(history); i++ {
+		if !history[i].Timestamp.Before(cutoff) {
+			break
+		}
+	}
+	return history[i:]
+}
+
+// conditionHolds evaluates whether r's condition is true right now, given
+// the retained history (which always ends with the latest sample).
+func conditionHolds(r *Rule, history []Sample, latest Sample) bool {
+	switch r.Kind {
+	case Increase:
+		if len(history) == 0 {
+			return false
+		}
+		delta := fieldValue(latest, r.Field) - fieldValue(history[0], r.Field)
+		return compare(r.Op, delta, r.Threshold)
+	case Growth:
+		if len(history) == 0 {
+			return false
+		}
+		first := fieldValue(history[0], r.Field)
+		if first == 0 {
+			return false
+		}
+		elapsed := latest.Timestamp.Sub(history[0].Timestamp)
+		if elapsed <= 0 || r.Per <= 0 {
+			return false
+		}
+		growth := (fieldValue(latest, r.Field) - first) / first
+		normalized := growth * (float64(r.Per) / float64(elapsed))
+		return compare(r.Op, normalized, r.Threshold)
+	default:
+		return compare(r.Op, fieldValue(latest, r.Field), r.Threshold)
+	}
+}
+
+// notifyAll runs every one of r's notifiers for the transition to status
+// ("firing" or "resolved"), logging (rather than failing the tick) any
+// notifier error.
+func notifyAll(ctx context.Context, r *Rule, s Sample, status string) {
+	for _, n := range r.Notifiers {
+		if err := n.Notify(ctx, r, s, status); err != nil {
+			log.Printf("alerts: rule %q notifier %T failed: %v", r.Name, n, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// recordingNotifier records every status transition it's notified of.
+type recordingNotifier struct {
+	statuses []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, r *Rule, s Sample, status string) error {
+	n.statuses = append(n.statuses, status)
+	return nil
+}
+
+func TestEvaluateRuleThresholdFiresAndResolves(t *testing.T) {
+	rec := &recordingNotifier{}
+	r := &Rule{
+		Name:      "high-cpu",
+		Field:     "CurrentRate",
+		Kind:      Threshold,
+		Op:        GreaterThan,
+		Threshold: 0.9,
+		Notifiers: []Notifier{rec},
+	}
+	e := NewEvaluator([]*Rule{r})
+	base := time.Unix(0, 0)
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base, CurrentRate: 0.5})
+	if got := e.states[stateKey(r, "worker")].state; got != stateResolved {
+		t.Fatalf("state after below-threshold sample = %v, want resolved", got)
+	}
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base.Add(time.Second), CurrentRate: 0.95})
+	if got := e.states[stateKey(r, "worker")].state; got != stateFiring {
+		t.Fatalf("state after above-threshold sample (For=0) = %v, want firing", got)
+	}
+	if len(rec.statuses) != 1 || rec.statuses[0] != "firing" {
+		t.Fatalf("statuses = %v, want [firing]", rec.statuses)
+	}
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base.Add(2 * time.Second), CurrentRate: 0.1})
+	if got := e.states[stateKey(r, "worker")].state; got != stateResolved {
+		t.Fatalf("state after dropping back below threshold = %v, want resolved", got)
+	}
+	if len(rec.statuses) != 2 || rec.statuses[1] != "resolved" {
+		t.Fatalf("statuses = %v, want [firing resolved]", rec.statuses)
+	}
+}
+
+func TestEvaluateRuleThresholdHonorsFor(t *testing.T) {
+	rec := &recordingNotifier{}
+	r := &Rule{
+		Name:      "high-cpu-sustained",
+		Field:     "CurrentRate",
+		Kind:      Threshold,
+		Op:        GreaterThan,
+		Threshold: 0.9,
+		For:       5 * time.Second,
+		Notifiers: []Notifier{rec},
+	}
+	e := NewEvaluator([]*Rule{r})
+	base := time.Unix(0, 0)
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base, CurrentRate: 0.95})
+	if got := e.states[stateKey(r, "worker")].state; got != statePending {
+		t.Fatalf("state right after crossing threshold = %v, want pending", got)
+	}
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base.Add(2 * time.Second), CurrentRate: 0.95})
+	if got := e.states[stateKey(r, "worker")].state; got != statePending {
+		t.Fatalf("state before For has elapsed = %v, want pending", got)
+	}
+	if len(rec.statuses) != 0 {
+		t.Fatalf("statuses = %v, want none before For has elapsed", rec.statuses)
+	}
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base.Add(6 * time.Second), CurrentRate: 0.95})
+	if got := e.states[stateKey(r, "worker")].state; got != stateFiring {
+		t.Fatalf("state once For has elapsed = %v, want firing", got)
+	}
+	if len(rec.statuses) != 1 || rec.statuses[0] != "firing" {
+		t.Fatalf("statuses = %v, want [firing]", rec.statuses)
+	}
+}
+
+func TestEvaluateRuleIncreaseWithinWindow(t *testing.T) {
+	r := &Rule{
+		Name:      "restart-spike",
+		Field:     "TimesRestarted",
+		Kind:      Increase,
+		Op:        GreaterThan,
+		Threshold: 3,
+		Within:    10 * time.Minute,
+	}
+	e := NewEvaluator([]*Rule{r})
+	base := time.Unix(0, 0)
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base, TimesRestarted: 1})
+	if got := e.states[stateKey(r, "worker")].state; got != stateResolved {
+		t.Fatalf("state after first sample = %v, want resolved (nothing to compare against yet)", got)
+	}
+
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base.Add(5 * time.Minute), TimesRestarted: 5})
+	if got := e.states[stateKey(r, "worker")].state; got != stateFiring {
+		t.Fatalf("state after a 4-restart increase within the window = %v, want firing", got)
+	}
+
+	// Once the first sample ages out of the window, the increase is
+	// measured against the new oldest sample instead.
+	e.evaluateRule(context.Background(), r, Sample{Role: "worker", Timestamp: base.Add(20 * time.Minute), TimesRestarted: 6})
+	if got := e.states[stateKey(r, "worker")].state; got != stateResolved {
+		t.Fatalf("state once the spike ages out of the window = %v, want resolved", got)
+	}
+}
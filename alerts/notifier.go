@@ -0,0 +1,138 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Notifier is told about a rule's pending->firing and firing->resolved
+// transitions. Implementations should be safe for concurrent use, since a
+// single Notifier can be shared across rules.
+type Notifier interface {
+	Notify(ctx context.Context, r *Rule, s Sample, status string) error
+}
+
+// rateLimited wraps a Notifier with a token-bucket limiter so a flapping
+// process can't spam it; a denied notification is dropped silently rather
+// than queued, since alerts are only useful while timely.
+type rateLimited struct {
+	next    Notifier
+	limiter *rate.Limiter
+}
+
+// WithRateLimit wraps next so it fires no more than once per every
+// 1/eventsPerSecond, with a burst of burst.
+func WithRateLimit(next Notifier, eventsPerSecond float64, burst int) Notifier {
+	return &rateLimited{next: next, limiter: rate.NewLimiter(rate.Limit(eventsPerSecond), burst)}
+}
+
+func (n *rateLimited) Notify(ctx context.Context, r *Rule, s Sample, status string) error {
+	if !n.limiter.Allow() {
+		return nil
+	}
+	return n.next.Notify(ctx, r, s, status)
+}
+
+// webhookNotifier POSTs the firing/resolved Sample as JSON to a URL.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a Notifier that POSTs JSON bodies to url.
+func NewWebhookNotifier(url string) Notifier {
+	return &webhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// webhookPayload is the JSON body POSTed to a webhook notifier.
+type webhookPayload struct {
+	Rule   string `json:"rule"`
+	Status string `json:"status"`
+	Sample Sample `json:"sample"`
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, r *Rule, s Sample, status string) error {
+	body, err := json.Marshal(webhookPayload{Rule: r.Name, Status: status, Sample: s})
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// execNotifier runs a shell command with the firing/resolved Sample's
+// fields passed in as environment variables.
+type execNotifier struct {
+	command string
+	args    []string
+}
+
+// NewExecNotifier returns a Notifier that runs command with args on each
+// transition.
+func NewExecNotifier(command string, args ...string) Notifier {
+	return &execNotifier{command: command, args: args}
+}
+
+func (n *execNotifier) Notify(ctx context.Context, r *Rule, s Sample, status string) error {
+	cmd := exec.CommandContext(ctx, n.command, n.args...)
+	cmd.Env = append(os.Environ(),
+		"ZEEKMON_ALERT_RULE="+r.Name,
+		"ZEEKMON_ALERT_STATUS="+status,
+		"ZEEKMON_ALERT_ROLE="+s.Role,
+		fmt.Sprintf("ZEEKMON_ALERT_CURRENT_RATE=%f", s.CurrentRate),
+		fmt.Sprintf("ZEEKMON_ALERT_TIMES_RESTARTED=%d", s.TimesRestarted),
+		fmt.Sprintf("ZEEKMON_ALERT_RSS_BYTES=%d", s.RSSBytes),
+		fmt.Sprintf("ZEEKMON_ALERT_VIRT_MEMORY_BYTES=%d", s.VirtMemoryBytes),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("exec %s: %w (output: %s)", n.command, err, out)
+	}
+	return nil
+}
+
+// syslogNotifier appends a line to a syslog facility.
+type syslogNotifier struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogNotifier returns a Notifier that writes to the named syslog tag
+// under facility (e.g. syslog.LOG_DAEMON).
+func NewSyslogNotifier(facility syslog.Priority, tag string) (Notifier, error) {
+	w, err := syslog.New(facility, tag)
+	if err != nil {
+		return nil, fmt.Errorf("connect to syslog: %w", err)
+	}
+	return &syslogNotifier{writer: w}, nil
+}
+
+func (n *syslogNotifier) Notify(ctx context.Context, r *Rule, s Sample, status string) error {
+	msg := fmt.Sprintf("zeekmon alert %s: rule=%q role=%q current_rate=%f times_restarted=%d rss_bytes=%d virt_memory_bytes=%d",
+		status, r.Name, s.Role, s.CurrentRate, s.TimesRestarted, s.RSSBytes, s.VirtMemoryBytes)
+	if status == "firing" {
+		return n.writer.Warning(msg)
+	}
+	return n.writer.Info(msg)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultPollPeriod is used for any target whose config omits poll_period.
+const defaultPollPeriod = 5 * time.Second
+
+// Duration is a time.Duration that unmarshals the same way under both YAML
+// and JSON: either a human-readable string ("30s", "5m") or a raw
+// nanosecond count. encoding/json has no notion of time.Duration, so a
+// bare "poll_period": "30s" config would otherwise parse fine in YAML but
+// fail (or silently mean 30 nanoseconds) in JSON.
+type Duration time.Duration
+
+// Duration returns d as a time.Duration.
+func (d Duration) Duration() time.Duration { return time.Duration(d) }
+
+func (d *Duration) fromAny(v interface{}) error {
+	switch t := v.(type) {
+	case float64:
+		*d = Duration(time.Duration(t))
+	case string:
+		parsed, err := time.ParseDuration(t)
+		if err != nil {
+			return fmt.Errorf("parse duration %q: %w", t, err)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration value %v", v)
+	}
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return d.fromAny(v)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	return d.fromAny(v)
+}
+
+// TargetConfig is the on-disk representation of a single monitored target.
+// Kind selects which fields are required: "exe_path" needs ExePath,
+// "cgroup" needs CgroupParent/CgroupID, and "container_id" needs CIDFile
+// (and optionally CgroupParent, which defaults to "docker").
+type TargetConfig struct {
+	Role         string   `json:"role" yaml:"role"`
+	Kind         string   `json:"kind" yaml:"kind"`
+	ExePath      string   `json:"exe_path,omitempty" yaml:"exe_path,omitempty"`
+	CgroupParent string   `json:"cgroup_parent,omitempty" yaml:"cgroup_parent,omitempty"`
+	CgroupID     string   `json:"cgroup_id,omitempty" yaml:"cgroup_id,omitempty"`
+	CIDFile      string   `json:"cid_file,omitempty" yaml:"cid_file,omitempty"`
+	PollPeriod   Duration `json:"poll_period,omitempty" yaml:"poll_period,omitempty"`
+}
+
+// Config is the top-level on-disk config file listing every Target zeekmon
+// should monitor.
+type Config struct {
+	Targets []TargetConfig `json:"targets" yaml:"targets"`
+}
+
+// LoadConfig reads a Config from path, dispatching on file extension:
+// ".yaml"/".yml" is parsed as YAML, anything else as JSON.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse yaml config %s: %w", path, err)
+		}
+	} else if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse json config %s: %w", path, err)
+	}
+
+	for i := range cfg.Targets {
+		if cfg.Targets[i].PollPeriod == 0 {
+			cfg.Targets[i].PollPeriod = Duration(defaultPollPeriod)
+		}
+	}
+	return &cfg, nil
+}
+
+// BuildTargets converts every TargetConfig into a concrete Target.
+func (c *Config) BuildTargets() ([]Target, error) {
+	targets := make([]Target, 0, len(c.Targets))
+	for _, tc := range c.Targets {
+		t, err := tc.build()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func (tc TargetConfig) build() (Target, error) {
+	switch tc.Kind {
+	case "exe_path":
+		if tc.ExePath == "" {
+			return nil, fmt.Errorf("target %q: exe_path is required for kind=exe_path", tc.Role)
+		}
+		return NewByExePath(tc.Role, tc.ExePath, tc.PollPeriod.Duration()), nil
+	case "cgroup":
+		if tc.CgroupID == "" {
+			return nil, fmt.Errorf("target %q: cgroup_id is required for kind=cgroup", tc.Role)
+		}
+		return NewByCgroup(tc.Role, tc.CgroupParent, tc.CgroupID, tc.PollPeriod.Duration()), nil
+	case "container_id":
+		if tc.CIDFile == "" {
+			return nil, fmt.Errorf("target %q: cid_file is required for kind=container_id", tc.Role)
+		}
+		return NewByContainerID(tc.Role, tc.CIDFile, tc.CgroupParent, tc.PollPeriod.Duration()), nil
+	default:
+		return nil, fmt.Errorf("target %q: unknown kind %q (expected exe_path, cgroup or container_id)", tc.Role, tc.Kind)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "math"
+
+// Time constants, in seconds, for the 1/5/15-minute CPU rate EWMAs exposed
+// as IntervalReport.CPURate1m/5m/15m, matching /proc/loadavg's averaging
+// windows.
+const (
+	ewmaTau1m  = 60.0
+	ewmaTau5m  = 300.0
+	ewmaTau15m = 900.0
+)
+
+// cpuEWMA tracks the exponentially weighted moving averages backing
+// CPURate1m/5m/15m. The zero value is ready to use: the first call to
+// Update seeds all three rates from instantRate rather than dragging them
+// up from zero.
+type cpuEWMA struct {
+	rate1m, rate5m, rate15m float64
+	initialized             bool
+}
+
+// Update folds instantRate, sampled intervalSeconds ago, into the three
+// EWMAs and returns their current values in (1m, 5m, 15m) order.
+func (e *cpuEWMA) Update(instantRate, intervalSeconds float64) (rate1m, rate5m, rate15m float64) {
+	if !e.initialized {
+		e.rate1m, e.rate5m, e.rate15m = instantRate, instantRate, instantRate
+		e.initialized = true
+		return e.rate1m, e.rate5m, e.rate15m
+	}
+
+	e.rate1m += ewmaAlpha(intervalSeconds, ewmaTau1m) * (instantRate - e.rate1m)
+	e.rate5m += ewmaAlpha(intervalSeconds, ewmaTau5m) * (instantRate - e.rate5m)
+	e.rate15m += ewmaAlpha(intervalSeconds, ewmaTau15m) * (instantRate - e.rate15m)
+	return e.rate1m, e.rate5m, e.rate15m
+}
+
+// ewmaAlpha computes the smoothing factor for an EWMA with time constant
+// tauSeconds updated every intervalSeconds: alpha = 1 - exp(-interval/tau).
+func ewmaAlpha(intervalSeconds, tauSeconds float64) float64 {
+	return 1 - math.Exp(-intervalSeconds/tauSeconds)
+}
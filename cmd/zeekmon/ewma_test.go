@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCpuEWMAUpdateSeedsFromFirstSample(t *testing.T) {
+	var e cpuEWMA
+	rate1m, rate5m, rate15m := e.Update(0.5, 10)
+	if rate1m != 0.5 || rate5m != 0.5 || rate15m != 0.5 {
+		t.Errorf("first Update() = (%v, %v, %v), want all seeded to 0.5", rate1m, rate5m, rate15m)
+	}
+}
+
+func TestCpuEWMAUpdateConverges(t *testing.T) {
+	var e cpuEWMA
+	e.Update(0, 10)
+
+	var rate1m, rate5m, rate15m float64
+	for i := 0; i < 50; i++ {
+		rate1m, rate5m, rate15m = e.Update(1.0, 10)
+	}
+
+	const tolerance = 1e-3
+	if diff := rate1m - 1.0; diff > tolerance || diff < -tolerance {
+		t.Errorf("rate1m = %v, want ~1.0 after converging", rate1m)
+	}
+	// The 1-minute average should track a sustained step change faster
+	// than the 15-minute average.
+	if rate1m <= rate5m || rate5m <= rate15m {
+		t.Errorf("expected rate1m > rate5m > rate15m while climbing toward steady state, got %v, %v, %v", rate1m, rate5m, rate15m)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// HistoryPoint is a single downsampled point returned by GET
+// /api/v1/history: the rate/memory fields are averaged over the bucket,
+// while TimesRestated (a monotonic counter) takes the bucket's maximum.
+type HistoryPoint struct {
+	Timestamp       time.Time `json:"timestamp"`
+	CurrentRate     float64   `json:"current_rate"`
+	WindowRate      float64   `json:"window_rate"`
+	LifetimeRate    float64   `json:"lifetime_rate"`
+	VirtMemoryBytes uint      `json:"virtual_memory_bytes"`
+	RSSBytes        int       `json:"rss_bytes"`
+	TimesRestated   uint64    `json:"times_restarted"`
+}
+
+// historyBucketAccum accumulates the reports falling into a single
+// downsampling window.
+type historyBucketAccum struct {
+	ts                                 time.Time
+	count                              int
+	currentSum, windowSum, lifetimeSum float64
+	vmemSum                            uint
+	rssSum                             int
+	maxRestarts                        uint64
+}
+
+// Downsample buckets reports into step-wide windows, keyed by the window's
+// start time, and averages the rate/memory fields within each window. A
+// non-positive step disables bucketing and returns one point per report.
+func Downsample(reports []*IntervalReport, step time.Duration) []HistoryPoint {
+	if step <= 0 {
+		points := make([]HistoryPoint, len(reports))
+		for i, r := range reports {
+			points[i] = pointFromReport(r)
+		}
+		return points
+	}
+
+	buckets := make(map[int64]*historyBucketAccum)
+	var order []int64
+	for _, r := range reports {
+		key := r.Timestamp.Unix() / int64(step.Seconds())
+		b, ok := buckets[key]
+		if !ok {
+			b = &historyBucketAccum{ts: r.Timestamp.Truncate(step)}
+			buckets[key] = b
+			order = append(order, key)
+		}
+		b.count++
+		b.currentSum += r.CurrentRate
+		b.windowSum += r.WindowRate
+		b.lifetimeSum += r.LifetimeRate
+		b.vmemSum += r.VirtMemoryBytes
+		b.rssSum += r.RSSBytes
+		if r.TimesRestated > b.maxRestarts {
+			b.maxRestarts = r.TimesRestated
+		}
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	points := make([]HistoryPoint, 0, len(order))
+	for _, key := range order {
+		b := buckets[key]
+		points = append(points, HistoryPoint{
+			Timestamp:       b.ts,
+			CurrentRate:     b.currentSum / float64(b.count),
+			WindowRate:      b.windowSum / float64(b.count),
+			LifetimeRate:    b.lifetimeSum / float64(b.count),
+			VirtMemoryBytes: b.vmemSum / uint(b.count),
+			RSSBytes:        b.rssSum / b.count,
+			TimesRestated:   b.maxRestarts,
+		})
+	}
+	return points
+}
+
+func pointFromReport(r *IntervalReport) HistoryPoint {
+	return HistoryPoint{
+		Timestamp:       r.Timestamp,
+		CurrentRate:     r.CurrentRate,
+		WindowRate:      r.WindowRate,
+		LifetimeRate:    r.LifetimeRate,
+		VirtMemoryBytes: r.VirtMemoryBytes,
+		RSSBytes:        r.RSSBytes,
+		TimesRestated:   r.TimesRestated,
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownsampleBucketsAndAverages(t *testing.T) {
+	base := time.Unix(0, 0).UTC()
+
+	reports := []*IntervalReport{
+		{Timestamp: base, CurrentRate: 1, TimesRestated: 1},
+		{Timestamp: base.Add(10 * time.Second), CurrentRate: 3, TimesRestated: 2},
+		{Timestamp: base.Add(70 * time.Second), CurrentRate: 5, TimesRestated: 1},
+	}
+
+	points := Downsample(reports, time.Minute)
+	if len(points) != 2 {
+		t.Fatalf("len(points) = %d, want 2", len(points))
+	}
+
+	if got, want := points[0].CurrentRate, 2.0; got != want {
+		t.Errorf("points[0].CurrentRate = %v, want %v", got, want)
+	}
+	if got, want := points[0].TimesRestated, uint64(2); got != want {
+		t.Errorf("points[0].TimesRestated = %v, want %v (max within bucket)", got, want)
+	}
+	if got, want := points[1].CurrentRate, 5.0; got != want {
+		t.Errorf("points[1].CurrentRate = %v, want %v", got, want)
+	}
+}
+
+func TestDownsampleNonPositiveStepReturnsOnePointPerReport(t *testing.T) {
+	base := time.Unix(0, 0).UTC()
+	reports := []*IntervalReport{
+		{Timestamp: base, CurrentRate: 1},
+		{Timestamp: base.Add(time.Second), CurrentRate: 2},
+	}
+
+	points := Downsample(reports, 0)
+	if len(points) != len(reports) {
+		t.Fatalf("len(points) = %d, want %d", len(points), len(reports))
+	}
+	if points[1].CurrentRate != 2 {
+		t.Errorf("points[1].CurrentRate = %v, want 2", points[1].CurrentRate)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// historyBucket is the single BoltDB bucket history records are stored in,
+// keyed by "<role>/<unix_nanos>" so a prefix scan over a role returns its
+// samples in chronological order.
+var historyBucket = []byte("history")
+
+// HistoryStore is an embedded, append-only time-series store for
+// IntervalReports, backed by BoltDB. Summaries only ever keeps the latest
+// report per role in memory; HistoryStore is what gives zeekmon a
+// long-running view across restarts.
+type HistoryStore struct {
+	db        *bolt.DB
+	retention time.Duration
+}
+
+// OpenHistoryStore opens (creating if necessary) a BoltDB file at path,
+// retaining samples no older than retention. Pass retention <= 0 to keep
+// everything forever.
+func OpenHistoryStore(path string, retention time.Duration) (*HistoryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open history store %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(historyBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init history store %s: %w", path, err)
+	}
+	return &HistoryStore{db: db, retention: retention}, nil
+}
+
+// Close closes the underlying BoltDB file.
+func (h *HistoryStore) Close() error {
+	return h.db.Close()
+}
+
+// historyKey builds the "<role>/<unix_nanos>" key a report is stored under.
+func historyKey(role string, t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s/%020d", role, t.UnixNano()))
+}
+
+// Append writes r to the store under its role and timestamp.
+func (h *HistoryStore) Append(r *IntervalReport) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	return h.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(historyBucket).Put(historyKey(r.Role, r.Timestamp), data)
+	})
+}
+
+// Query returns every report stored for role with a timestamp in
+// [from, to], in chronological order.
+func (h *HistoryStore) Query(role string, from, to time.Time) ([]*IntervalReport, error) {
+	prefix := []byte(role + "/")
+	var out []*IntervalReport
+	err := h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var r IntervalReport
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("unmarshal history record %s: %w", k, err)
+			}
+			if r.Timestamp.Before(from) || r.Timestamp.After(to) {
+				continue
+			}
+			out = append(out, &r)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Prune deletes every record older than the store's retention, relative to
+// now. It is a no-op when retention is unset. Callers should invoke it
+// periodically, e.g. once per report tick.
+func (h *HistoryStore) Prune(now time.Time) error {
+	if h.retention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-h.retention)
+
+	return h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		c := b.Cursor()
+
+		var stale [][]byte
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var r IntervalReport
+			if err := json.Unmarshal(v, &r); err != nil {
+				continue
+			}
+			if r.Timestamp.Before(cutoff) {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
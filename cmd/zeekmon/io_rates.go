@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// ioRates is the disk/network analog of the CPU CurrentRate/WindowRate/
+// LifetimeRate figures on IntervalReport, expressed in bytes (or packets)
+// per second for a single counter.
+type ioRates struct {
+	CurrentRate  float64 `json:"current_rate"`
+	WindowRate   float64 `json:"window_rate"`
+	StandardDev  float64 `json:"standard_dev"`
+	LifetimeRate float64 `json:"lifetime_rate"`
+}
+
+// fsSample is a single free/used/total snapshot for a spool directory,
+// sampled via syscall.Statfs.
+type fsSample struct {
+	FreeBytes  uint64 `json:"free_bytes"`
+	UsedBytes  uint64 `json:"used_bytes"`
+	TotalBytes uint64 `json:"total_bytes"`
+}
+
+// ioSample pairs a raw, monotonically increasing counter with the time it
+// was read; computeIORate consumes a ring of these the same way CPU samples
+// feed WindowRate.
+type ioSample struct {
+	Value     uint64
+	Timestamp time.Time
+}
+
+// computeIORate derives CurrentRate (the derivative between the two most
+// recent samples), WindowRate/StandardDev (mean and population standard
+// deviation of all per-sample rates in history) and LifetimeRate (the
+// average rate between the first and last sample), mirroring how CPU
+// CurrentRate/WindowRate/LifetimeRate are computed.
+func computeIORate(history []ioSample) ioRates {
+	if len(history) == 0 {
+		return ioRates{}
+	}
+
+	rates := make([]float64, 0, len(history)-1)
+	for i := 1; i < len(history); i++ {
+		dt := history[i].Timestamp.Sub(history[i-1].Timestamp).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		// A counter that goes backwards means the process (or the kernel
+		// counter itself) restarted between samples; treat that tick as a
+		// reset rather than underflowing the uint64 subtraction into a
+		// huge bogus rate.
+		if history[i].Value < history[i-1].Value {
+			continue
+		}
+		dv := float64(history[i].Value - history[i-1].Value)
+		rates = append(rates, dv/dt)
+	}
+
+	var current float64
+	if len(rates) > 0 {
+		current = rates[len(rates)-1]
+	}
+	mean, stddev := meanStdDev(rates)
+
+	first, last := history[0], history[len(history)-1]
+	var lifetime float64
+	if lifetimeSeconds := last.Timestamp.Sub(first.Timestamp).Seconds(); lifetimeSeconds > 0 && last.Value >= first.Value {
+		lifetime = float64(last.Value-first.Value) / lifetimeSeconds
+	}
+
+	return ioRates{
+		CurrentRate:  current,
+		WindowRate:   mean,
+		StandardDev:  stddev,
+		LifetimeRate: lifetime,
+	}
+}
+
+// meanStdDev returns the arithmetic mean and population standard deviation
+// of vals, or (0, 0) for an empty slice.
+func meanStdDev(vals []float64) (mean, stddev float64) {
+	if len(vals) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	mean = sum / float64(len(vals))
+
+	var sqDiffSum float64
+	for _, v := range vals {
+		d := v - mean
+		sqDiffSum += d * d
+	}
+	return mean, math.Sqrt(sqDiffSum / float64(len(vals)))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeIORate(t *testing.T) {
+	base := time.Unix(1000, 0)
+
+	rates := computeIORate([]ioSample{
+		{Value: 100, Timestamp: base},
+		{Value: 300, Timestamp: base.Add(1 * time.Second)},
+		{Value: 700, Timestamp: base.Add(2 * time.Second)},
+	})
+
+	if got, want := rates.CurrentRate, 400.0; got != want {
+		t.Errorf("CurrentRate = %v, want %v", got, want)
+	}
+	if got, want := rates.LifetimeRate, 300.0; got != want {
+		t.Errorf("LifetimeRate = %v, want %v", got, want)
+	}
+	if got, want := rates.WindowRate, 300.0; got != want {
+		t.Errorf("WindowRate = %v, want %v", got, want)
+	}
+}
+
+func TestComputeIORateCounterReset(t *testing.T) {
+	base := time.Unix(1000, 0)
+
+	// The counter drops between the second and third sample, as happens
+	// when the underlying process restarts; that tick must be skipped
+	// rather than underflowing into a huge bogus rate.
+	rates := computeIORate([]ioSample{
+		{Value: 900, Timestamp: base},
+		{Value: 1000, Timestamp: base.Add(1 * time.Second)},
+		{Value: 50, Timestamp: base.Add(2 * time.Second)},
+	})
+
+	if got, want := rates.CurrentRate, 100.0; got != want {
+		t.Errorf("CurrentRate = %v, want %v", got, want)
+	}
+	if got, want := rates.LifetimeRate, 0.0; got != want {
+		t.Errorf("LifetimeRate = %v, want %v (counter went backwards over the full window)", got, want)
+	}
+}
+
+func TestComputeIORateEmpty(t *testing.T) {
+	if got := (computeIORate(nil)); got != (ioRates{}) {
+		t.Errorf("computeIORate(nil) = %+v, want zero value", got)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// procIOCounters are the raw, monotonically increasing counters read from
+// /proc/<pid>/io for a single sample.
+type procIOCounters struct {
+	RChar      uint64
+	WChar      uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// readProcIO parses /proc/<pid>/io.
+func readProcIO(pid int) (procIOCounters, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return procIOCounters{}, fmt.Errorf("open /proc/%d/io: %w", pid, err)
+	}
+	defer f.Close()
+
+	var c procIOCounters
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		val, err := strconv.ParseUint(strings.TrimSpace(fields[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(fields[0]) {
+		case "rchar":
+			c.RChar = val
+		case "wchar":
+			c.WChar = val
+		case "read_bytes":
+			c.ReadBytes = val
+		case "write_bytes":
+			c.WriteBytes = val
+		}
+	}
+	return c, scanner.Err()
+}
+
+// netIOCounters are the raw, monotonically increasing per-interface
+// counters read from /proc/<pid>/net/dev for a single sample.
+type netIOCounters struct {
+	RxBytes   uint64
+	RxPackets uint64
+	TxBytes   uint64
+	TxPackets uint64
+}
+
+// readProcNetDev parses /proc/<pid>/net/dev into a map keyed by interface
+// name, skipping the two fixed header lines.
+func readProcNetDev(pid int) (map[string]netIOCounters, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return nil, fmt.Errorf("open /proc/%d/net/dev: %w", pid, err)
+	}
+	defer f.Close()
+
+	counters := make(map[string]netIOCounters)
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if line <= 2 {
+			continue
+		}
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) < 16 {
+			continue
+		}
+		rxBytes, _ := strconv.ParseUint(fields[0], 10, 64)
+		rxPackets, _ := strconv.ParseUint(fields[1], 10, 64)
+		txBytes, _ := strconv.ParseUint(fields[8], 10, 64)
+		txPackets, _ := strconv.ParseUint(fields[9], 10, 64)
+		counters[strings.TrimSpace(parts[0])] = netIOCounters{
+			RxBytes:   rxBytes,
+			RxPackets: rxPackets,
+			TxBytes:   txBytes,
+			TxPackets: txPackets,
+		}
+	}
+	return counters, scanner.Err()
+}
+
+// statfsSpoolDir samples free/used/total bytes for dir via syscall.Statfs,
+// used to watch log spool directories for imminent disk exhaustion.
+func statfsSpoolDir(dir string) (fsSample, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return fsSample{}, fmt.Errorf("statfs %s: %w", dir, err)
+	}
+	total := uint64(st.Blocks) * uint64(st.Bsize)
+	free := uint64(st.Bfree) * uint64(st.Bsize)
+	return fsSample{
+		FreeBytes:  free,
+		UsedBytes:  total - free,
+		TotalBytes: total,
+	}, nil
+}
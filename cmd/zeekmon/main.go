@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/szaydel/zeekmon/alerts"
+)
+
+var (
+	metricsAddr = flag.String("metrics-addr", ":9191", "bind address for the /metrics, /metrics/{role} and /healthz HTTP endpoints")
+
+	reporterKind     = flag.String("reporter", "stdout", "reporter backend to use: stdout, influxdb or graphite")
+	reporterEndpoint = flag.String("reporter-endpoint", "", "endpoint for the selected reporter (InfluxDB write URL or Graphite host:port)")
+	reporterInterval = flag.Duration("reporter-push-interval", 10*time.Second, "minimum interval between pushes for push-based reporters (influxdb, graphite)")
+
+	configPath = flag.String("config", "zeekmon.yaml", "path to the YAML/JSON config listing targets to monitor")
+
+	historyDBPath = flag.String("history-db", "", "path to a BoltDB file for persisting history; history tracking and the /api/v1/history endpoint are disabled when empty")
+	historyRetain = flag.Duration("history-retention", 24*time.Hour, "how long to retain history records for; ignored when -history-db is empty")
+
+	rulesPath = flag.String("rules", "", "path to a YAML alert rules file; alerting is disabled when empty")
+
+	spoolDirs stringSliceFlag
+)
+
+func init() {
+	flag.Var(&spoolDirs, "spool-dir", "spool/temp directory to sample free/used/total bytes for on each tick (may be repeated)")
+}
+
+// stringSliceFlag accumulates repeated occurrences of a flag into a slice,
+// e.g. -spool-dir /var/spool/zeek -spool-dir /tmp/zeek.
+type stringSliceFlag []string
+
+func (f *stringSliceFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringSliceFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// sampleSpoolFS statfs's every configured -spool-dir, keyed by directory
+// path, for inclusion in IntervalReport.SpoolFS.
+func sampleSpoolFS() map[string]fsSample {
+	if len(spoolDirs) == 0 {
+		return nil
+	}
+	samples := make(map[string]fsSample, len(spoolDirs))
+	for _, dir := range spoolDirs {
+		s, err := statfsSpoolDir(dir)
+		if err != nil {
+			log.Printf("spool dir %s: %v", dir, err)
+			continue
+		}
+		samples[dir] = s
+	}
+	return samples
+}
+
+// defaultTargets is used when -config points at a file that doesn't exist,
+// preserving zeekmon's old behavior of watching a single bare-metal bro
+// binary with no config file at all.
+func defaultTargets() []Target {
+	return []Target{
+		NewByExePath("bro", "/workspace/sandbox/bin/bro", defaultPollPeriod),
+	}
+}
+
+// loadTargets loads targets from -config, falling back to defaultTargets if
+// the file does not exist.
+func loadTargets() []Target {
+	if _, err := os.Stat(*configPath); os.IsNotExist(err) {
+		return defaultTargets()
+	}
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	targets, err := cfg.BuildTargets()
+	if err != nil {
+		log.Fatalf("build targets: %v", err)
+	}
+	return targets
+}
+
+// cachedDiscover wraps a Target's Discover in a closure that only actually
+// re-runs it once per the Target's own PollPeriod, so each target can be
+// polled at its configured rate even though startMonitors drives discovery
+// on its own fixed tick. ctx is threaded into every Discover call so a
+// blocking discovery step (e.g. ByContainerID waiting for its CIDFile)
+// still respects shutdown.
+func cachedDiscover(ctx context.Context, t Target) func() []*ProcInfo {
+	var (
+		mu     sync.Mutex
+		last   time.Time
+		cached []*ProcInfo
+	)
+	return func() []*ProcInfo {
+		mu.Lock()
+		defer mu.Unlock()
+		if !last.IsZero() && time.Since(last) < t.PollPeriod() {
+			return cached
+		}
+		procs, err := t.Discover(ctx)
+		if err != nil {
+			log.Printf("target %s: discover failed: %v", t.Role(), err)
+			return cached
+		}
+		cached = procs
+		last = time.Now()
+		return cached
+	}
+}
+
+// newReporter builds the Reporter selected by -reporter, exiting if an
+// unknown kind is given or a push reporter is selected without an endpoint.
+func newReporter() Reporter {
+	switch *reporterKind {
+	case "stdout":
+		return NewStdoutReporter()
+	case "influxdb":
+		if *reporterEndpoint == "" {
+			log.Fatal("-reporter-endpoint is required when -reporter=influxdb")
+		}
+		return NewInfluxDBReporter(*reporterEndpoint, *reporterInterval)
+	case "graphite":
+		if *reporterEndpoint == "" {
+			log.Fatal("-reporter-endpoint is required when -reporter=graphite")
+		}
+		return NewGraphiteReporter(*reporterEndpoint, *reporterInterval)
+	default:
+		log.Fatalf("unknown reporter %q: expected stdout, influxdb or graphite", *reporterKind)
+		return nil
+	}
+}
+
+// type Count int
+// type Role string
+
+// const Proxy Role = "proxy"
+// const Worker Role = "worker"
+// const Manager Role = "manager"
+// const Logger Role = "logger"
+
+// func expectedCount(r Role) Count {
+// 	m := map[Role]Count{
+// 		Proxy:   2,
+// 		Worker:  1,
+// 		Manager: 1,
+// 		Logger:  1,
+// 	}
+// 	return m[r]
+// }
+
+func main() {
+	flag.Parse()
+
+	// trap Ctrl+C and call cancel on the context
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	defer func() {
+		signal.Stop(sigChan)
+		cancel()
+	}()
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var historyStore *HistoryStore
+	if *historyDBPath != "" {
+		store, err := OpenHistoryStore(*historyDBPath, *historyRetain)
+		if err != nil {
+			log.Fatalf("open history store: %v", err)
+		}
+		defer store.Close()
+		historyStore = store
+	}
+
+	var evaluator *alerts.Evaluator
+	if *rulesPath != "" {
+		rules, err := alerts.LoadRules(*rulesPath)
+		if err != nil {
+			log.Fatalf("load alert rules: %v", err)
+		}
+		evaluator = alerts.NewEvaluator(rules)
+	}
+
+	intervalReportChan := make(chan *IntervalReport)
+	for _, t := range loadTargets() {
+		go startMonitors(ctx, intervalReportChan, t.Role(), cachedDiscover(ctx, t))
+	}
+	go startIntervalReport(ctx, intervalReportChan, newReporter(), historyStore, evaluator)
+	go func() {
+		if err := startMetricsServer(ctx, *metricsAddr, historyStore); err != nil {
+			log.Printf("metrics server exited with: %v", err)
+		}
+	}()
+	<-ctx.Done()
+}
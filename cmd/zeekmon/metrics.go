@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// promMetric describes a single Prometheus metric: its name, HELP text and
+// TYPE. HELP/TYPE lines are only emitted once per metric per scrape,
+// regardless of how many roles are being reported.
+type promMetric struct {
+	name string
+	help string
+	typ  string
+}
+
+// promMetrics lists, in emission order, every gauge derived directly from an
+// IntervalReport. The histogram built from RateHistogram is handled
+// separately since its bucket labels vary per report.
+var promMetrics = []promMetric{
+	{"bro_pid", "Process ID of the monitored bro process.", "gauge"},
+	{"bro_process_age_seconds", "Seconds elapsed since the process was first observed.", "gauge"},
+	{"bro_virtual_memory_bytes", "Virtual memory size of the process, in bytes.", "gauge"},
+	{"bro_rss_bytes", "Resident set size of the process, in bytes.", "gauge"},
+	{"bro_cpu_window_rate", "CPU usage rate averaged over the reporting window.", "gauge"},
+	{"bro_cpu_lifetime_rate", "CPU usage rate averaged over the entire lifetime of the process.", "gauge"},
+	{"bro_cpu_current_rate", "CPU usage rate derived from the two most recent samples.", "gauge"},
+	{"bro_cpu_rate_1m", "CPU usage rate, exponentially weighted over a 1-minute time constant.", "gauge"},
+	{"bro_cpu_rate_5m", "CPU usage rate, exponentially weighted over a 5-minute time constant.", "gauge"},
+	{"bro_cpu_rate_15m", "CPU usage rate, exponentially weighted over a 15-minute time constant.", "gauge"},
+	{"bro_times_restarted", "Number of times the process has been observed to restart.", "counter"},
+}
+
+// gaugeLines renders the fixed gauges/counters in promMetrics for a single
+// IntervalReport, in the same order they are declared.
+func (i IntervalReport) gaugeLines() []string {
+	role := i.Role
+	return []string{
+		fmt.Sprintf("bro_pid{role=%q} %d", role, i.PID),
+		fmt.Sprintf("bro_process_age_seconds{role=%q} %d", role, int(i.Age.Seconds())),
+		fmt.Sprintf("bro_virtual_memory_bytes{role=%q} %d", role, i.VirtMemoryBytes),
+		fmt.Sprintf("bro_rss_bytes{role=%q} %d", role, i.RSSBytes),
+		fmt.Sprintf("bro_cpu_window_rate{role=%q} %f", role, i.WindowRate),
+		fmt.Sprintf("bro_cpu_lifetime_rate{role=%q} %f", role, i.LifetimeRate),
+		fmt.Sprintf("bro_cpu_current_rate{role=%q} %f", role, i.CurrentRate),
+		fmt.Sprintf("bro_cpu_rate_1m{role=%q} %f", role, i.CPURate1m),
+		fmt.Sprintf("bro_cpu_rate_5m{role=%q} %f", role, i.CPURate5m),
+		fmt.Sprintf("bro_cpu_rate_15m{role=%q} %f", role, i.CPURate15m),
+		fmt.Sprintf("bro_times_restarted{role=%q} %d", role, i.TimesRestated),
+	}
+}
+
+// histogramLines renders RateHistogram as a Prometheus histogram. Map keys
+// are treated as cumulative "le" bucket bounds; the largest bucket doubles
+// as bro_cpu_rate_histogram_count since RateHistogram does not track a
+// separate total.
+func (i IntervalReport) histogramLines() []string {
+	if len(i.RateHistogram) == 0 {
+		return nil
+	}
+	buckets := make([]string, 0, len(i.RateHistogram))
+	for le := range i.RateHistogram {
+		buckets = append(buckets, le)
+	}
+	sort.Strings(buckets)
+
+	lines := make([]string, 0, len(buckets)+1)
+	var count int64
+	for _, le := range buckets {
+		v := i.RateHistogram[le]
+		if v > count {
+			count = v
+		}
+		lines = append(lines, fmt.Sprintf("bro_cpu_rate_histogram_bucket{role=%q,le=%q} %d", i.Role, le, v))
+	}
+	lines = append(lines, fmt.Sprintf("bro_cpu_rate_histogram_count{role=%q} %d", i.Role, count))
+	return lines
+}
+
+// diskIONetIOLines renders the per-device DiskIO and per-interface NetIO
+// throughput figures for a single IntervalReport as bro_disk_io_* and
+// bro_net_io_* gauges, labeled by device/interface.
+func (i IntervalReport) diskIONetIOLines() []string {
+	lines := make([]string, 0, len(i.DiskIO)+len(i.NetIO))
+	for counter, r := range i.DiskIO {
+		lines = append(lines,
+			fmt.Sprintf("bro_disk_io_current_rate{role=%q,counter=%q} %f", i.Role, counter, r.CurrentRate),
+			fmt.Sprintf("bro_disk_io_window_rate{role=%q,counter=%q} %f", i.Role, counter, r.WindowRate),
+			fmt.Sprintf("bro_disk_io_lifetime_rate{role=%q,counter=%q} %f", i.Role, counter, r.LifetimeRate),
+		)
+	}
+	for iface, r := range i.NetIO {
+		lines = append(lines,
+			fmt.Sprintf("bro_net_io_current_rate{role=%q,interface=%q} %f", i.Role, iface, r.CurrentRate),
+			fmt.Sprintf("bro_net_io_window_rate{role=%q,interface=%q} %f", i.Role, iface, r.WindowRate),
+			fmt.Sprintf("bro_net_io_lifetime_rate{role=%q,interface=%q} %f", i.Role, iface, r.LifetimeRate),
+		)
+	}
+	return lines
+}
+
+// spoolFSLines renders the free/used/total bytes sampled for every
+// configured spool directory as bro_spool_fs_*_bytes gauges, labeled by
+// directory path.
+func (i IntervalReport) spoolFSLines() []string {
+	lines := make([]string, 0, len(i.SpoolFS)*3)
+	for dir, s := range i.SpoolFS {
+		lines = append(lines,
+			fmt.Sprintf("bro_spool_fs_free_bytes{role=%q,dir=%q} %d", i.Role, dir, s.FreeBytes),
+			fmt.Sprintf("bro_spool_fs_used_bytes{role=%q,dir=%q} %d", i.Role, dir, s.UsedBytes),
+			fmt.Sprintf("bro_spool_fs_total_bytes{role=%q,dir=%q} %d", i.Role, dir, s.TotalBytes),
+		)
+	}
+	return lines
+}
+
+// Prometheus renders every metric known for the given role as a full
+// Prometheus exposition document, including HELP and TYPE lines. It returns
+// errNoInfoForRole if role has never reported in.
+func (s *Summaries) Prometheus(role string) (string, error) {
+	s.mtx.RLock()
+	rep := s.safeIntervalReport(role)
+	s.mtx.RUnlock()
+	if rep == nil {
+		return "", errNoInfoForRole
+	}
+	return renderPrometheus([]*IntervalReport{rep}), nil
+}
+
+// PrometheusAll renders every role currently tracked as a single Prometheus
+// exposition document.
+func (s *Summaries) PrometheusAll() string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	l := make([]*IntervalReport, 0, len(s.m))
+	for role := range s.m {
+		l = append(l, s.safeIntervalReport(role))
+	}
+	return renderPrometheus(l)
+}
+
+// renderPrometheus emits HELP/TYPE lines once per gauge/counter metric
+// followed by the sample lines for every report passed in, then does the
+// same for the per-role rate histogram.
+func renderPrometheus(reports []*IntervalReport) string {
+	var b strings.Builder
+
+	gauges := make([][]string, len(reports))
+	for idx, r := range reports {
+		gauges[idx] = r.gaugeLines()
+	}
+	for mIdx, m := range promMetrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.typ)
+		for _, lines := range gauges {
+			b.WriteString(lines[mIdx])
+			b.WriteByte('\n')
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP bro_cpu_rate_histogram Distribution of observed CPU usage rates.\n")
+	fmt.Fprintf(&b, "# TYPE bro_cpu_rate_histogram histogram\n")
+	for _, r := range reports {
+		for _, line := range r.histogramLines() {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP bro_disk_io_current_rate Disk I/O counter rate between the two most recent samples, in units/sec.\n")
+	fmt.Fprintf(&b, "# TYPE bro_disk_io_current_rate gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_disk_io_window_rate Disk I/O counter rate averaged over the reporting window, in units/sec.\n")
+	fmt.Fprintf(&b, "# TYPE bro_disk_io_window_rate gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_disk_io_lifetime_rate Disk I/O counter rate averaged over the process lifetime, in units/sec.\n")
+	fmt.Fprintf(&b, "# TYPE bro_disk_io_lifetime_rate gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_net_io_current_rate Network I/O counter rate between the two most recent samples, in units/sec.\n")
+	fmt.Fprintf(&b, "# TYPE bro_net_io_current_rate gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_net_io_window_rate Network I/O counter rate averaged over the reporting window, in units/sec.\n")
+	fmt.Fprintf(&b, "# TYPE bro_net_io_window_rate gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_net_io_lifetime_rate Network I/O counter rate averaged over the process lifetime, in units/sec.\n")
+	fmt.Fprintf(&b, "# TYPE bro_net_io_lifetime_rate gauge\n")
+	for _, r := range reports {
+		for _, line := range r.diskIONetIOLines() {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP bro_spool_fs_free_bytes Free bytes on a configured spool/temp directory's filesystem.\n")
+	fmt.Fprintf(&b, "# TYPE bro_spool_fs_free_bytes gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_spool_fs_used_bytes Used bytes on a configured spool/temp directory's filesystem.\n")
+	fmt.Fprintf(&b, "# TYPE bro_spool_fs_used_bytes gauge\n")
+	fmt.Fprintf(&b, "# HELP bro_spool_fs_total_bytes Total bytes on a configured spool/temp directory's filesystem.\n")
+	fmt.Fprintf(&b, "# TYPE bro_spool_fs_total_bytes gauge\n")
+	for _, r := range reports {
+		for _, line := range r.spoolFSLines() {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHistoryStep is used for GET /api/v1/history requests that omit
+// ?step=.
+const defaultHistoryStep = time.Minute
+
+// startMetricsServer starts an HTTP server on addr exposing "/metrics",
+// "/metrics/<role>", "/healthz" and, when store is non-nil, the
+// "/api/v1/history" query API. It blocks until ctx is canceled, at which
+// point the server is shut down and startMetricsServer returns nil.
+func startMetricsServer(ctx context.Context, addr string, store *HistoryStore) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handleMetrics)
+	mux.HandleFunc("/metrics/", handleMetricsRole)
+	mux.HandleFunc("/healthz", handleHealthz)
+	if store != nil {
+		mux.HandleFunc("/api/v1/history", handleHistory(store))
+	}
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleMetrics serves the Prometheus exposition document for every role
+// currently tracked by metricsReport.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(metricsReport.PrometheusAll()))
+}
+
+// handleMetricsRole serves the Prometheus exposition document scoped to a
+// single role, e.g. "/metrics/worker".
+func handleMetricsRole(w http.ResponseWriter, r *http.Request) {
+	role := strings.TrimPrefix(r.URL.Path, "/metrics/")
+	if role == "" {
+		handleMetrics(w, r)
+		return
+	}
+
+	body, err := metricsReport.Prometheus(role)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(body))
+}
+
+// handleHealthz reports liveness of the zeekmon process itself, independent
+// of whether any bro processes have been discovered yet.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+// handleHistory returns the handler for GET /api/v1/history?role=...&from=
+// ...&to=...&step=..., serving a downsampled series of HistoryPoints out of
+// store. from/to are RFC3339 timestamps defaulting to the last hour; step
+// is a Go duration string defaulting to defaultHistoryStep.
+func handleHistory(store *HistoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		role := q.Get("role")
+		if role == "" {
+			http.Error(w, "role is required", http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now()
+		from, err := parseHistoryTime(q.Get("from"), now.Add(-time.Hour))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("bad from: %v", err), http.StatusBadRequest)
+			return
+		}
+		to, err := parseHistoryTime(q.Get("to"), now)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("bad to: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		step := defaultHistoryStep
+		if s := q.Get("step"); s != "" {
+			step, err = time.ParseDuration(s)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("bad step: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		reports, err := store.Query(role, from, to)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Downsample(reports, step))
+	}
+}
+
+// parseHistoryTime parses s as RFC3339, returning fallback for an empty
+// string.
+func parseHistoryTime(s string, fallback time.Time) (time.Time, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNoInfoForRole is returned when a role has no IntervalReport recorded
+// yet.
+var errNoInfoForRole = errors.New("no info available for role")
+
+// reportInterval is how often startIntervalReport ticks to hand the
+// current summaries to the configured Reporter, and how often startMonitors
+// re-samples every discovered process.
+var reportInterval = 10 * time.Second
+
+// metricsReport is the process-wide Summaries singleton every monitor
+// goroutine inserts into and every reporter/HTTP handler reads from.
+var metricsReport = &Summaries{m: make(map[string]*IntervalReport)}
+
+// clkTck is the kernel's USER_HZ, used to convert /proc/<pid>/stat's
+// utime/stime ticks into seconds. 100 is the near-universal value on
+// Linux; it is not exposed as a clean syscall without cgo, so it is
+// hard-coded the same way most pure-Go /proc samplers do.
+const clkTck = 100.0
+
+// ProcInfo is a single discovered process: the data startMonitors uses to
+// seed an IntervalReport and keep sampling it tick over tick.
+type ProcInfo struct {
+	PID  int
+	Role string
+}
+
+// findProcsByName scans /proc for processes whose /proc/<pid>/exe resolves
+// to exePath, returning one ProcInfo per match.
+func findProcsByName(exePath string) []*ProcInfo {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		log.Printf("findProcsByName: read /proc: %v", err)
+		return nil
+	}
+
+	var procs []*ProcInfo
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		link, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+		if err != nil || link != exePath {
+			continue
+		}
+		procs = append(procs, &ProcInfo{PID: pid})
+	}
+	return procs
+}
+
+// cpuWindowSize bounds how many instantaneous CPU rate samples WindowRate/
+// StandardDev are computed over.
+const cpuWindowSize = 5
+
+// procState is the per-role sampling state startMonitors maintains across
+// ticks for the currently-discovered PID under that role.
+type procState struct {
+	pid           int
+	role          string
+	initTimestamp time.Time
+	restarts      uint64
+
+	lastCPUTicks uint64
+	lastSampleAt time.Time
+	cpuWindow    []float64
+	ewma         cpuEWMA
+
+	diskHistory map[string][]ioSample
+	netHistory  map[string][]ioSample
+}
+
+// newProcState seeds sampling state for a newly discovered PID under role.
+func newProcState(role string, pid int, restarts uint64) *procState {
+	return &procState{
+		pid:           pid,
+		role:          role,
+		initTimestamp: time.Now(),
+		restarts:      restarts,
+		diskHistory:   make(map[string][]ioSample),
+		netHistory:    make(map[string][]ioSample),
+	}
+}
+
+// startMonitors polls discover on each tick, maintaining per-PID sampling
+// state for role and emitting an IntervalReport into out every time. A PID
+// that disappears and is replaced by a new one counts as a restart. It
+// runs until ctx is canceled.
+func startMonitors(ctx context.Context, out chan<- *IntervalReport, role string, discover func() []*ProcInfo) {
+	tick := time.NewTicker(reportInterval)
+	defer tick.Stop()
+
+	var st *procState
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			procs := discover()
+			if len(procs) == 0 {
+				continue
+			}
+			pid := procs[0].PID
+
+			if st == nil {
+				st = newProcState(role, pid, 0)
+			} else if st.pid != pid {
+				st = newProcState(role, pid, st.restarts+1)
+			}
+
+			rep, err := sampleProc(st)
+			if err != nil {
+				log.Printf("role %s: sample pid %d: %v", role, pid, err)
+				continue
+			}
+
+			select {
+			case out <- rep:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// sampleProc takes one sample of st's PID, updating its sampling state and
+// returning the resulting IntervalReport.
+func sampleProc(st *procState) (*IntervalReport, error) {
+	now := time.Now()
+
+	utimeTicks, stimeTicks, err := readProcStat(st.pid)
+	if err != nil {
+		return nil, fmt.Errorf("read /proc/%d/stat: %w", st.pid, err)
+	}
+	totalTicks := utimeTicks + stimeTicks
+
+	virtBytes, rssBytes, err := readProcMem(st.pid)
+	if err != nil {
+		return nil, fmt.Errorf("read /proc/%d/status: %w", st.pid, err)
+	}
+
+	var instantRate, dtSeconds float64
+	if !st.lastSampleAt.IsZero() {
+		dtSeconds = now.Sub(st.lastSampleAt).Seconds()
+		if dtSeconds > 0 && totalTicks >= st.lastCPUTicks {
+			instantRate = float64(totalTicks-st.lastCPUTicks) / clkTck / dtSeconds
+		}
+	}
+	st.lastCPUTicks = totalTicks
+	st.lastSampleAt = now
+
+	st.cpuWindow = append(st.cpuWindow, instantRate)
+	if len(st.cpuWindow) > cpuWindowSize {
+		st.cpuWindow = st.cpuWindow[len(st.cpuWindow)-cpuWindowSize:]
+	}
+	windowRate, standardDev := meanStdDev(st.cpuWindow)
+
+	var lifetimeRate float64
+	if age := now.Sub(st.initTimestamp).Seconds(); age > 0 {
+		lifetimeRate = float64(totalTicks) / clkTck / age
+	}
+
+	// Use the measured gap between samples rather than the nominal
+	// reportInterval: a skipped tick (discover() coming up empty) would
+	// otherwise make Update think only one interval had passed when
+	// several actually did, under-smoothing the 1/5/15m rates afterward.
+	ewmaDt := dtSeconds
+	if ewmaDt <= 0 {
+		ewmaDt = reportInterval.Seconds()
+	}
+	rate1m, rate5m, rate15m := st.ewma.Update(instantRate, ewmaDt)
+
+	diskIO, err := sampleDiskIO(st, now)
+	if err != nil {
+		log.Printf("role %s: disk io for pid %d: %v", st.role, st.pid, err)
+	}
+	netIO, err := sampleNetIO(st, now)
+	if err != nil {
+		log.Printf("role %s: net io for pid %d: %v", st.role, st.pid, err)
+	}
+
+	return &IntervalReport{
+		PID:             st.pid,
+		Role:            st.role,
+		InitTimestamp:   st.initTimestamp,
+		Timestamp:       now,
+		Age:             now.Sub(st.initTimestamp),
+		WindowRate:      windowRate,
+		StandardDev:     standardDev,
+		LifetimeRate:    lifetimeRate,
+		CurrentRate:     instantRate,
+		CPURate1m:       rate1m,
+		CPURate5m:       rate5m,
+		CPURate15m:      rate15m,
+		TimesRestated:   st.restarts,
+		VirtMemoryBytes: virtBytes,
+		RSSBytes:        rssBytes,
+		DiskIO:          diskIO,
+		NetIO:           netIO,
+		SpoolFS:         sampleSpoolFS(),
+	}, nil
+}
+
+// ioHistoryWindow bounds how many samples an ioSample ring buffer retains
+// per counter.
+const ioHistoryWindow = cpuWindowSize
+
+// sampleDiskIO reads /proc/<pid>/io and folds each counter into st's
+// per-counter ring buffer, returning the resulting rates.
+func sampleDiskIO(st *procState, now time.Time) (map[string]ioRates, error) {
+	counters, err := readProcIO(st.pid)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]uint64{
+		"rchar":       counters.RChar,
+		"wchar":       counters.WChar,
+		"read_bytes":  counters.ReadBytes,
+		"write_bytes": counters.WriteBytes,
+	}
+	return foldIOCounters(st.diskHistory, raw, now), nil
+}
+
+// sampleNetIO reads /proc/<pid>/net/dev and folds each interface's counters
+// into st's per-interface ring buffers, returning the resulting rates.
+func sampleNetIO(st *procState, now time.Time) (map[string]ioRates, error) {
+	ifaces, err := readProcNetDev(st.pid)
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[string]ioRates, len(ifaces)*2)
+	for iface, c := range ifaces {
+		raw := map[string]uint64{
+			iface + "_rx_bytes":   c.RxBytes,
+			iface + "_rx_packets": c.RxPackets,
+			iface + "_tx_bytes":   c.TxBytes,
+			iface + "_tx_packets": c.TxPackets,
+		}
+		for k, v := range foldIOCounters(st.netHistory, raw, now) {
+			rates[k] = v
+		}
+	}
+	return rates, nil
+}
+
+// foldIOCounters appends each raw counter value onto its ring buffer in
+// history, trims it to ioHistoryWindow, and returns the computed ioRates
+// per counter.
+func foldIOCounters(history map[string][]ioSample, raw map[string]uint64, now time.Time) map[string]ioRates {
+	rates := make(map[string]ioRates, len(raw))
+	for name, value := range raw {
+		samples := append(history[name], ioSample{Value: value, Timestamp: now})
+		if len(samples) > ioHistoryWindow {
+			samples = samples[len(samples)-ioHistoryWindow:]
+		}
+		history[name] = samples
+		rates[name] = computeIORate(samples)
+	}
+	return rates
+}
+
+// readProcStat reads the utime/stime CPU tick counters for pid out of
+// /proc/<pid>/stat. The comm field is skipped by searching for the last
+// ")", since it may itself contain spaces or parens.
+func readProcStat(pid int) (utimeTicks, stimeTicks uint64, err error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	line := string(data)
+	rparen := strings.LastIndex(line, ")")
+	if rparen < 0 {
+		return 0, 0, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(line[rparen+1:])
+	if len(fields) < 13 {
+		return 0, 0, fmt.Errorf("short /proc/%d/stat", pid)
+	}
+
+	utimeTicks, err = strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse utime: %w", err)
+	}
+	stimeTicks, err = strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse stime: %w", err)
+	}
+	return utimeTicks, stimeTicks, nil
+}
+
+// readProcMem reads VmSize/VmRSS out of /proc/<pid>/status, converting from
+// kB to bytes.
+func readProcMem(pid int) (virtBytes uint, rssBytes int, err error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "VmSize:"):
+			kb, err := parseStatusKB(line)
+			if err == nil {
+				virtBytes = uint(kb) * 1024
+			}
+		case strings.HasPrefix(line, "VmRSS:"):
+			kb, err := parseStatusKB(line)
+			if err == nil {
+				rssBytes = int(kb) * 1024
+			}
+		}
+	}
+	return virtBytes, rssBytes, scanner.Err()
+}
+
+// parseStatusKB parses the numeric kB value out of a "Key:\t123 kB" line
+// from /proc/<pid>/status.
+func parseStatusKB(line string) (int64, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed status line %q", line)
+	}
+	return strconv.ParseInt(fields[1], 10, 64)
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"encoding/json"
 	"fmt"
 	"log"
 	"math"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/szaydel/zeekmon/alerts"
 )
 
 // IntervalReport is a point in time view of process' CPU usage with three
@@ -29,10 +31,23 @@ type IntervalReport struct {
 	StandardDev     float64          `json:"standard_dev"`
 	LifetimeRate    float64          `json:"lifetime_rate"`
 	CurrentRate     float64          `json:"current_rate"`
+	CPURate1m       float64          `json:"cpu_rate_1m"`
+	CPURate5m       float64          `json:"cpu_rate_5m"`
+	CPURate15m      float64          `json:"cpu_rate_15m"`
 	TimesRestated   uint64           `json:"times_restarted"`
 	VirtMemoryBytes uint             `json:"virtual_memory_bytes"`
 	RSSBytes        int              `json:"rss_bytes"`
 	RateHistogram   map[string]int64 `json:"rate_histogram"`
+
+	// DiskIO holds per-device throughput derived from /proc/<pid>/io,
+	// keyed by counter name ("rchar", "wchar", "read_bytes", "write_bytes").
+	DiskIO map[string]ioRates `json:"disk_io,omitempty"`
+	// NetIO holds per-interface throughput derived from
+	// /proc/<pid>/net/dev, keyed by interface name.
+	NetIO map[string]ioRates `json:"net_io,omitempty"`
+	// SpoolFS holds free/used/total bytes for any configured spool/temp
+	// directories, keyed by directory path.
+	SpoolFS map[string]fsSample `json:"spool_fs,omitempty"`
 }
 
 func (i IntervalReport) String() string {
@@ -46,31 +61,69 @@ func (i IntervalReport) String() string {
 	return pid + "\n" + first_seen + "\n" + age + "\n" + vmem + "\n"
 }
 
-func startIntervalReport(c <-chan *IntervalReport) {
-	// on each tick, print out all summaries to stdout
+// startIntervalReport drains interval reports off c into metricsReport and,
+// on every tick, hands the current set of summaries to reporter. When store
+// is non-nil, every report is also appended to it and pruned according to
+// its retention policy, giving zeekmon a long-running history across
+// restarts. When evaluator is non-nil, every report is also run through its
+// alert rules on each tick. It runs until c is closed or a nil value is
+// received, or until ctx is canceled.
+func startIntervalReport(ctx context.Context, c <-chan *IntervalReport, reporter Reporter, store *HistoryStore, evaluator *alerts.Evaluator) {
 	tick := time.NewTicker(reportInterval)
+	defer tick.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case v := <-c:
 			if v == nil {
 				return
 			}
 			metricsReport.Insert(v)
+			if store != nil {
+				if err := store.Append(v); err != nil {
+					log.Printf("Failed to append to history store: %v", err)
+				}
+			}
 		case <-tick.C:
-			if !metricsReport.Empty() {
-				data, err := metricsReport.ToJSON()
-				if err != nil {
-					log.Printf("Failed to produce report with: %v", err)
-					continue
+			if store != nil {
+				if err := store.Prune(time.Now()); err != nil {
+					log.Printf("Failed to prune history store: %v", err)
+				}
+			}
+			if metricsReport.Empty() {
+				continue
+			}
+			all, err := metricsReport.All()
+			if err != nil {
+				log.Printf("Failed to produce report with: %v", err)
+				continue
+			}
+			if err := reporter.Report(ctx, all); err != nil {
+				log.Printf("Reporter %T failed with: %v", reporter, err)
+			}
+			if evaluator != nil {
+				for _, rep := range all {
+					evaluator.Evaluate(ctx, sampleFromReport(rep))
 				}
-				fmt.Fprintln(os.Stdout, string(data))
 			}
-		default:
-			<-time.NewTimer(1 * time.Second).C
 		}
 	}
 }
 
+// sampleFromReport adapts an IntervalReport into the alerts.Sample the
+// alerts package evaluates rules against.
+func sampleFromReport(r *IntervalReport) alerts.Sample {
+	return alerts.Sample{
+		Role:            r.Role,
+		Timestamp:       r.Timestamp,
+		CurrentRate:     r.CurrentRate,
+		TimesRestarted:  r.TimesRestated,
+		RSSBytes:        int64(r.RSSBytes),
+		VirtMemoryBytes: uint64(r.VirtMemoryBytes),
+	}
+}
+
 // Summaries is used as a global singleton to keep track of running
 // statistics for processes being monitored.
 type Summaries struct {
@@ -99,9 +152,9 @@ func (s *Summaries) Empty() bool {
 	return len(s.m) == 0
 }
 
+// findRole looks up role in the summaries map. Callers must already hold
+// s.mtx (for reading or writing).
 func (s *Summaries) findRole(role string) *IntervalReport {
-	s.mtx.RLock()
-	defer s.mtx.RUnlock()
 	if v, ok := s.m[role]; ok {
 		return v
 	}
@@ -110,10 +163,9 @@ func (s *Summaries) findRole(role string) *IntervalReport {
 
 // safeIntervalReport converts any NaNs to -1's, because JSON is brain-dead
 // and the idiots behind it apparently don't understand that NaNs, -Inf and +Inf
-// are actually a thing.
+// are actually a thing. Callers must already hold s.mtx (for reading or
+// writing).
 func (s *Summaries) safeIntervalReport(role string) *IntervalReport {
-	s.mtx.RLock()
-	defer s.mtx.RUnlock()
 	var rep, safeRep *IntervalReport
 	if rep = s.findRole(role); rep == nil {
 		return nil
@@ -129,10 +181,16 @@ func (s *Summaries) safeIntervalReport(role string) *IntervalReport {
 		StandardDev:     rep.StandardDev,
 		LifetimeRate:    rep.LifetimeRate,
 		CurrentRate:     rep.CurrentRate,
+		CPURate1m:       rep.CPURate1m,
+		CPURate5m:       rep.CPURate5m,
+		CPURate15m:      rep.CPURate15m,
 		RateHistogram:   rep.RateHistogram,
 		TimesRestated:   rep.TimesRestated,
 		VirtMemoryBytes: rep.VirtMemoryBytes,
 		RSSBytes:        rep.RSSBytes,
+		DiskIO:          rep.DiskIO,
+		NetIO:           rep.NetIO,
+		SpoolFS:         rep.SpoolFS,
 	}
 
 	if math.IsNaN(safeRep.CurrentRate) {
@@ -147,6 +205,15 @@ func (s *Summaries) safeIntervalReport(role string) *IntervalReport {
 	if math.IsNaN(safeRep.WindowRate) {
 		safeRep.WindowRate = -1
 	}
+	if math.IsNaN(safeRep.CPURate1m) {
+		safeRep.CPURate1m = -1
+	}
+	if math.IsNaN(safeRep.CPURate5m) {
+		safeRep.CPURate5m = -1
+	}
+	if math.IsNaN(safeRep.CPURate15m) {
+		safeRep.CPURate15m = -1
+	}
 	return safeRep
 }
 
@@ -155,13 +222,12 @@ func (s *Summaries) safeIntervalReport(role string) *IntervalReport {
 // Multiple concurrent readers are possible, but only one writer is allowed.
 func (s *Summaries) RoleToJSON(role string) ([]byte, error) {
 	s.mtx.RLock()
-	defer s.mtx.RUnlock()
-	var rep *IntervalReport
+	rep := s.safeIntervalReport(role)
+	s.mtx.RUnlock()
 
-	if rep = s.safeIntervalReport(role); rep == nil {
+	if rep == nil {
 		return []byte{}, errNoInfoForRole
 	}
-
 	return json.Marshal(rep)
 }
 
@@ -182,15 +248,13 @@ func (s *Summaries) ToJSON() ([]byte, error) {
 func (s *Summaries) All() ([]*IntervalReport, error) {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
-	if s.Len() == 0 {
+	if len(s.m) == 0 {
 		return nil, errors.New("zero summaries currently available")
 	}
-	l := make([]*IntervalReport, s.Len())
+	l := make([]*IntervalReport, len(s.m))
 	var c = 0
 	for role := range s.m {
-		// l = append(l, s.safeIntervalReport(role))
 		l[c] = s.safeIntervalReport(role)
-		fmt.Printf("role => %v\n", role)
 		c++
 	}
 	return l, nil
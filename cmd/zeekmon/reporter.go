@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Reporter pushes or exposes a batch of IntervalReports on each report tick.
+// Implementations are free to push to a remote system (InfluxDB, Graphite)
+// or simply render locally (stdout); startIntervalReport treats them
+// identically.
+type Reporter interface {
+	Report(ctx context.Context, reports []*IntervalReport) error
+}
+
+// stdoutReporter writes the batch of reports as a single JSON array to
+// os.Stdout, preserving the original behavior of startIntervalReport.
+type stdoutReporter struct{}
+
+// NewStdoutReporter returns a Reporter that prints each batch of reports as
+// JSON to standard output.
+func NewStdoutReporter() Reporter {
+	return &stdoutReporter{}
+}
+
+func (r *stdoutReporter) Report(ctx context.Context, reports []*IntervalReport) error {
+	data, err := json.Marshal(reports)
+	if err != nil {
+		return fmt.Errorf("marshal reports: %w", err)
+	}
+	fmt.Fprintln(os.Stdout, string(data))
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// graphiteReporter pushes batches of IntervalReports to a Graphite carbon
+// listener as plaintext "zeekmon.<host>.<role>.<metric> <value> <epoch>\n"
+// lines. It pushes no more often than pushInterval, independent of the
+// caller's own report tick.
+type graphiteReporter struct {
+	addr         string
+	pushInterval time.Duration
+	host         string
+	dialTimeout  time.Duration
+	lastPush     time.Time
+}
+
+// NewGraphiteReporter returns a Reporter that writes carbon plaintext lines
+// to the TCP listener at addr, no more than once per pushInterval.
+func NewGraphiteReporter(addr string, pushInterval time.Duration) Reporter {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &graphiteReporter{
+		addr:         addr,
+		pushInterval: pushInterval,
+		host:         sanitizeGraphitePathComponent(host),
+		dialTimeout:  5 * time.Second,
+	}
+}
+
+func (r *graphiteReporter) Report(ctx context.Context, reports []*IntervalReport) error {
+	if !r.lastPush.IsZero() && time.Since(r.lastPush) < r.pushInterval {
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", r.addr, r.dialTimeout)
+	if err != nil {
+		return fmt.Errorf("dial graphite carbon listener: %w", err)
+	}
+	defer conn.Close()
+
+	epoch := time.Now().Unix()
+	for _, rep := range reports {
+		role := sanitizeGraphitePathComponent(rep.Role)
+		metrics := map[string]float64{
+			"window_rate":          rep.WindowRate,
+			"standard_dev":         rep.StandardDev,
+			"lifetime_rate":        rep.LifetimeRate,
+			"current_rate":         rep.CurrentRate,
+			"times_restarted":      float64(rep.TimesRestated),
+			"virtual_memory_bytes": float64(rep.VirtMemoryBytes),
+			"rss_bytes":            float64(rep.RSSBytes),
+		}
+		for name, value := range metrics {
+			line := fmt.Sprintf("zeekmon.%s.%s.%s %f %d\n", r.host, role, name, value, epoch)
+			if _, err := conn.Write([]byte(line)); err != nil {
+				return fmt.Errorf("write to graphite carbon listener: %w", err)
+			}
+		}
+	}
+
+	r.lastPush = time.Now()
+	return nil
+}
+
+// sanitizeGraphitePathComponent replaces dots, which would otherwise be
+// interpreted as metric path separators by Graphite, with underscores.
+func sanitizeGraphitePathComponent(s string) string {
+	return strings.ReplaceAll(s, ".", "_")
+}
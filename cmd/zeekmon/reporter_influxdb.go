@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// influxDBReporter pushes batches of IntervalReports to an InfluxDB HTTP
+// write endpoint using line protocol, under the "zeek" measurement. It
+// pushes no more often than pushInterval, independent of the caller's own
+// report tick.
+type influxDBReporter struct {
+	endpoint     string
+	pushInterval time.Duration
+	host         string
+	client       *http.Client
+	lastPush     time.Time
+}
+
+// NewInfluxDBReporter returns a Reporter that writes line protocol to the
+// InfluxDB /write endpoint at endpoint, no more than once per pushInterval.
+func NewInfluxDBReporter(endpoint string, pushInterval time.Duration) Reporter {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &influxDBReporter{
+		endpoint:     endpoint,
+		pushInterval: pushInterval,
+		host:         host,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (r *influxDBReporter) Report(ctx context.Context, reports []*IntervalReport) error {
+	if !r.lastPush.IsZero() && time.Since(r.lastPush) < r.pushInterval {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, rep := range reports {
+		fmt.Fprintf(&buf,
+			"zeek,role=%s,pid=%d,host=%s window_rate=%f,standard_dev=%f,lifetime_rate=%f,current_rate=%f,times_restarted=%di,virtual_memory_bytes=%di,rss_bytes=%di %d\n",
+			rep.Role, rep.PID, r.host,
+			rep.WindowRate, rep.StandardDev, rep.LifetimeRate, rep.CurrentRate,
+			rep.TimesRestated, rep.VirtMemoryBytes, rep.RSSBytes,
+			rep.Timestamp.UnixNano(),
+		)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, &buf)
+	if err != nil {
+		return fmt.Errorf("build influxdb write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push to influxdb: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("influxdb write returned %s", strings.TrimSpace(resp.Status))
+	}
+
+	r.lastPush = time.Now()
+	return nil
+}
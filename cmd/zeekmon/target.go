@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target locates the PIDs that should be monitored under a given role.
+// Concrete implementations discover PIDs by executable path, by cgroup, or
+// by container ID, so a single zeekmon process can watch Zeek running
+// bare-metal, under systemd/cgroups, or inside a container.
+type Target interface {
+	// Role is the label attached to every IntervalReport produced for PIDs
+	// discovered by this Target.
+	Role() string
+	// PollPeriod controls how often this Target should be re-discovered.
+	PollPeriod() time.Duration
+	// Discover returns the current set of processes for this Target. It
+	// respects ctx cancellation for any blocking discovery step, e.g.
+	// ByContainerID waiting for its CIDFile to appear.
+	Discover(ctx context.Context) ([]*ProcInfo, error)
+}
+
+// ByExePath discovers PIDs by matching against a configured executable
+// path, the strategy main() used to hard-code via findProcsByName.
+type ByExePath struct {
+	role       string
+	exePath    string
+	pollPeriod time.Duration
+}
+
+// NewByExePath returns a Target that discovers PIDs by executable path.
+func NewByExePath(role, exePath string, pollPeriod time.Duration) *ByExePath {
+	return &ByExePath{role: role, exePath: exePath, pollPeriod: pollPeriod}
+}
+
+func (t *ByExePath) Role() string              { return t.role }
+func (t *ByExePath) PollPeriod() time.Duration { return t.pollPeriod }
+
+func (t *ByExePath) Discover(ctx context.Context) ([]*ProcInfo, error) {
+	return findProcsByName(t.exePath), nil
+}
+
+// ByCgroup discovers PIDs by walking a cgroup's cgroup.procs file, as the
+// crunchstat Reporter does, supporting both the cgroup v1 per-controller
+// layout and the cgroup v2 unified layout.
+type ByCgroup struct {
+	role       string
+	parent     string
+	cid        string
+	pollPeriod time.Duration
+}
+
+// NewByCgroup returns a Target that discovers PIDs under
+// /sys/fs/cgroup/<parent>/<cid>.
+func NewByCgroup(role, parent, cid string, pollPeriod time.Duration) *ByCgroup {
+	return &ByCgroup{role: role, parent: parent, cid: cid, pollPeriod: pollPeriod}
+}
+
+func (t *ByCgroup) Role() string              { return t.role }
+func (t *ByCgroup) PollPeriod() time.Duration { return t.pollPeriod }
+
+// cgroupProcsPaths lists the candidate cgroup.procs locations for this
+// target's parent/cid, tried in order until one can be read: first the
+// cgroup v2 unified hierarchy, then the cgroup v1 "memory" controller.
+func (t *ByCgroup) cgroupProcsPaths() []string {
+	return []string{
+		filepath.Join("/sys/fs/cgroup", t.parent, t.cid, "cgroup.procs"),
+		filepath.Join("/sys/fs/cgroup/memory", t.parent, t.cid, "cgroup.procs"),
+	}
+}
+
+func (t *ByCgroup) Discover(ctx context.Context) ([]*ProcInfo, error) {
+	var lastErr error
+	for _, p := range t.cgroupProcsPaths() {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return procInfosFromPIDs(parsePIDList(data)), nil
+	}
+	return nil, fmt.Errorf("read cgroup.procs for %s/%s: %w", t.parent, t.cid, lastErr)
+}
+
+// ByContainerID discovers PIDs for a container whose ID is written to a
+// CIDFile by the container runtime, delegating the actual PID enumeration
+// to a ByCgroup once the ID is known.
+type ByContainerID struct {
+	role       string
+	cidFile    string
+	parent     string
+	pollPeriod time.Duration
+
+	mu  sync.Mutex
+	cid string
+}
+
+// NewByContainerID returns a Target that waits for cidFile to contain a
+// container ID, then discovers PIDs under /sys/fs/cgroup/<parent>/<cid>. If
+// parent is empty, "docker" is assumed.
+func NewByContainerID(role, cidFile, parent string, pollPeriod time.Duration) *ByContainerID {
+	if parent == "" {
+		parent = "docker"
+	}
+	return &ByContainerID{role: role, cidFile: cidFile, parent: parent, pollPeriod: pollPeriod}
+}
+
+func (t *ByContainerID) Role() string              { return t.role }
+func (t *ByContainerID) PollPeriod() time.Duration { return t.pollPeriod }
+
+// waitForCID blocks, polling cidFile, until it contains a non-empty
+// container ID or ctx is canceled.
+func (t *ByContainerID) waitForCID(ctx context.Context) (string, error) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		data, err := os.ReadFile(t.cidFile)
+		if err == nil {
+			if cid := strings.TrimSpace(string(data)); cid != "" {
+				return cid, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *ByContainerID) Discover(ctx context.Context) ([]*ProcInfo, error) {
+	t.mu.Lock()
+	cid := t.cid
+	t.mu.Unlock()
+
+	if cid == "" {
+		resolved, err := t.waitForCID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolve container id from %s: %w", t.cidFile, err)
+		}
+		t.mu.Lock()
+		t.cid = resolved
+		t.mu.Unlock()
+		cid = resolved
+	}
+
+	return (&ByCgroup{role: t.role, parent: t.parent, cid: cid}).Discover(ctx)
+}
+
+// parsePIDList parses the newline-delimited PID list found in a
+// cgroup.procs file, skipping any line that doesn't parse as an int.
+func parsePIDList(data []byte) []int {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pids := make([]int, 0, len(lines))
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		pid, err := strconv.Atoi(l)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// procInfosFromPIDs builds the minimal []*ProcInfo needed to feed
+// startMonitors from a bare PID list, e.g. as read from cgroup.procs.
+func procInfosFromPIDs(pids []int) []*ProcInfo {
+	procs := make([]*ProcInfo, 0, len(pids))
+	for _, pid := range pids {
+		procs = append(procs, &ProcInfo{PID: pid})
+	}
+	return procs
+}
@@ -0,0 +1,76 @@
+// Command zeekmonctl is a small CLI for ad-hoc inspection of a running
+// zeekmon process's history, hitting the same GET /api/v1/history endpoint
+// the HTTP API serves.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+func main() {
+	var (
+		addr = flag.String("addr", "http://localhost:9191", "base URL of the zeekmon metrics server")
+		role = flag.String("role", "", "role to query history for (required)")
+		from = flag.String("from", "", "RFC3339 start time (defaults to 1h ago)")
+		to   = flag.String("to", "", "RFC3339 end time (defaults to now)")
+		step = flag.String("step", "", "downsampling step, e.g. 1m (defaults to 1m)")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: zeekmonctl query -role <role> [-addr url] [-from time] [-to time] [-step dur]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || flag.Arg(0) != "query" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *role == "" {
+		log.Fatal("-role is required")
+	}
+
+	q := url.Values{}
+	q.Set("role", *role)
+	if *from != "" {
+		q.Set("from", *from)
+	}
+	if *to != "" {
+		q.Set("to", *to)
+	}
+	if *step != "" {
+		q.Set("step", *step)
+	}
+
+	resp, err := http.Get(*addr + "/api/v1/history?" + q.Encode())
+	if err != nil {
+		log.Fatalf("query history: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("history query returned %s: %s", resp.Status, body)
+	}
+
+	var pretty interface{}
+	if err := json.Unmarshal(body, &pretty); err != nil {
+		fmt.Println(string(body))
+		return
+	}
+	out, err := json.MarshalIndent(pretty, "", "  ")
+	if err != nil {
+		fmt.Println(string(body))
+		return
+	}
+	fmt.Println(string(out))
+}